@@ -0,0 +1,255 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// NewsItem is a single cached news article for a symbol, keyed
+// together with its publication date and source URL. RelevanceScore
+// and SentimentScore/SentimentLabel are specific to the symbol the
+// article was fetched for, not the article's overall sentiment.
+type NewsItem struct {
+	Date           string
+	URL            string
+	Title          string
+	Summary        string
+	RelevanceScore float64
+	SentimentScore float64
+	SentimentLabel string
+}
+
+// Cache is a SQLite-backed store for previously fetched quotes and
+// news, keyed by (symbol, date) for prices and (symbol, date, url)
+// for news. It lets repeated invocations for the same ticker avoid
+// re-hitting rate-limited upstream APIs.
+type Cache struct {
+	db      *sql.DB
+	refresh bool
+	maxAge  time.Duration
+}
+
+// OpenCache opens (creating if necessary) the SQLite database at path
+// and ensures its schema exists. refresh forces every lookup to be
+// treated as a miss; maxAge invalidates rows older than that duration
+// (zero means rows never expire by age).
+func OpenCache(path string, refresh bool, maxAge time.Duration) (*Cache, error) {
+	// -portfolio mode writes to this same DB from several goroutines at
+	// once, and SQLite only allows one writer at a time. busy_timeout
+	// makes a blocked writer wait instead of failing with
+	// SQLITE_BUSY; WAL lets readers proceed without waiting on it.
+	// Both are set via DSN pragmas, since database/sql pools multiple
+	// connections and a plain PRAGMA Exec only affects one of them.
+	dsn := fmt.Sprintf("file:%s?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)", path)
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("error opening cache db: %v", err)
+	}
+
+	const schema = `
+	CREATE TABLE IF NOT EXISTS prices (
+		symbol TEXT NOT NULL,
+		date TEXT NOT NULL,
+		open TEXT,
+		high TEXT,
+		low TEXT,
+		close TEXT,
+		volume TEXT,
+		fetched_at INTEGER NOT NULL,
+		PRIMARY KEY (symbol, date)
+	);
+	CREATE TABLE IF NOT EXISTS news (
+		symbol TEXT NOT NULL,
+		date TEXT NOT NULL,
+		url TEXT NOT NULL,
+		title TEXT,
+		summary TEXT,
+		relevance_score REAL,
+		sentiment_score REAL,
+		sentiment_label TEXT,
+		fetched_at INTEGER NOT NULL,
+		PRIMARY KEY (symbol, date, url)
+	);
+	CREATE TABLE IF NOT EXISTS news_fetch_log (
+		symbol TEXT NOT NULL PRIMARY KEY,
+		fetched_at INTEGER NOT NULL
+	);`
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error creating cache schema: %v", err)
+	}
+
+	return &Cache{db: db, refresh: refresh, maxAge: maxAge}, nil
+}
+
+func (c *Cache) Close() error {
+	return c.db.Close()
+}
+
+// cutoff returns the earliest fetched_at timestamp that is still
+// considered fresh, or 0 if rows never expire by age.
+func (c *Cache) cutoff() int64 {
+	if c.maxAge <= 0 {
+		return 0
+	}
+	return time.Now().Add(-c.maxAge).Unix()
+}
+
+// Prices returns the cached rows for symbol within [from, to], and
+// whether the cache can be trusted to fully cover that window (data
+// reaching back to from and up to to, and -refresh was not set).
+func (c *Cache) Prices(symbol string, from, to time.Time) ([]StockData, bool) {
+	if c.refresh {
+		return nil, false
+	}
+
+	rows, err := c.db.Query(
+		`SELECT date, open, high, low, close, volume FROM prices
+		 WHERE symbol = ? AND date >= ? AND date <= ? AND fetched_at >= ?
+		 ORDER BY date`,
+		symbol, from.Format("2006-01-02"), to.Format("2006-01-02"), c.cutoff(),
+	)
+	if err != nil {
+		return nil, false
+	}
+	defer rows.Close()
+
+	var data []StockData
+	for rows.Next() {
+		var d StockData
+		if err := rows.Scan(&d.Date, &d.Open, &d.High, &d.Low, &d.Close, &d.Volume); err != nil {
+			return nil, false
+		}
+		data = append(data, d)
+	}
+
+	if len(data) == 0 {
+		return nil, false
+	}
+
+	// Allow a few calendar days of slack at each edge so weekends and
+	// market holidays near the window boundary don't look like gaps.
+	oldest, _ := time.Parse("2006-01-02", data[0].Date)
+	newest, _ := time.Parse("2006-01-02", data[len(data)-1].Date)
+	covers := !oldest.After(from.AddDate(0, 0, 3)) && !newest.Before(to.AddDate(0, 0, -3))
+
+	return data, covers
+}
+
+// PutPrices upserts data into the prices table, stamping each row
+// with the current time so -max-age can later invalidate it.
+func (c *Cache) PutPrices(symbol string, data []StockData) error {
+	tx, err := c.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO prices (symbol, date, open, high, low, close, volume, fetched_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(symbol, date) DO UPDATE SET
+			open = excluded.open, high = excluded.high, low = excluded.low,
+			close = excluded.close, volume = excluded.volume, fetched_at = excluded.fetched_at`)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now().Unix()
+	for _, d := range data {
+		if _, err := stmt.Exec(symbol, d.Date, d.Open, d.High, d.Low, d.Close, d.Volume, now); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// News returns the cached articles for symbol within [from, to], and
+// whether the cache can be trusted (i.e. -refresh was not set and
+// PutNews has recorded a fetch for symbol within -max-age). Coverage
+// is tracked in news_fetch_log rather than inferred from the row
+// count, since a symbol can legitimately have zero qualifying
+// articles and that "nothing found" result is itself worth caching.
+func (c *Cache) News(symbol string, from, to time.Time) ([]NewsItem, bool) {
+	if c.refresh {
+		return nil, false
+	}
+
+	var fetchedAt int64
+	err := c.db.QueryRow(`SELECT fetched_at FROM news_fetch_log WHERE symbol = ?`, symbol).Scan(&fetchedAt)
+	if err != nil || fetchedAt < c.cutoff() {
+		return nil, false
+	}
+
+	rows, err := c.db.Query(
+		`SELECT date, url, title, summary, relevance_score, sentiment_score, sentiment_label FROM news
+		 WHERE symbol = ? AND date >= ? AND date <= ?
+		 ORDER BY date`,
+		symbol, from.Format("2006-01-02"), to.Format("2006-01-02"),
+	)
+	if err != nil {
+		return nil, false
+	}
+	defer rows.Close()
+
+	var items []NewsItem
+	for rows.Next() {
+		var n NewsItem
+		if err := rows.Scan(&n.Date, &n.URL, &n.Title, &n.Summary, &n.RelevanceScore, &n.SentimentScore, &n.SentimentLabel); err != nil {
+			return nil, false
+		}
+		items = append(items, n)
+	}
+
+	return items, true
+}
+
+// PutNews upserts news articles into the news table and records symbol
+// as freshly fetched in news_fetch_log, even when items is empty, so a
+// symbol with no qualifying articles is still considered cached.
+func (c *Cache) PutNews(symbol string, items []NewsItem) error {
+	tx, err := c.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().Unix()
+
+	if _, err := tx.Exec(`
+		INSERT INTO news_fetch_log (symbol, fetched_at) VALUES (?, ?)
+		ON CONFLICT(symbol) DO UPDATE SET fetched_at = excluded.fetched_at`,
+		symbol, now); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO news (symbol, date, url, title, summary, relevance_score, sentiment_score, sentiment_label, fetched_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(symbol, date, url) DO UPDATE SET
+			title = excluded.title, summary = excluded.summary,
+			relevance_score = excluded.relevance_score,
+			sentiment_score = excluded.sentiment_score,
+			sentiment_label = excluded.sentiment_label,
+			fetched_at = excluded.fetched_at`)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for _, n := range items {
+		if _, err := stmt.Exec(symbol, n.Date, n.URL, n.Title, n.Summary, n.RelevanceScore, n.SentimentScore, n.SentimentLabel, now); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
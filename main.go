@@ -9,40 +9,37 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
 )
 
+// StockData is one daily OHLCV row. In -adjusted mode, Open/High/Low/
+// Close are back-applied for splits so consumers can chart and compute
+// returns on a continuous series; RawOpen/RawHigh/RawLow/RawClose keep
+// the original as-quoted values alongside them. The Raw fields, like
+// AdjustedClose, Dividend and SplitCoefficient, are only filled in
+// -adjusted mode and are empty otherwise.
 type StockData struct {
-	Date        string
-	Open        string
-	High        string
-	Low         string
-	Close       string
-	Volume      string
-	NewsTitle   string
-	NewsSummary string
-}
-
-type AlphaVantageNewsResponse struct {
-	Feed []struct {
-		Title         string `json:"title"`
-		URL           string `json:"url"`
-		TimePublished string `json:"time_published"`
-		Summary       string `json:"summary"`
-	} `json:"feed"`
-}
-
-type AlphaVantageResponse struct {
-	TimeSeries map[string]struct {
-		Open   string `json:"1. open"`
-		High   string `json:"2. high"`
-		Low    string `json:"3. low"`
-		Close  string `json:"4. close"`
-		Volume string `json:"5. volume"`
-	} `json:"Time Series (Daily)"`
+	Date             string
+	Open             string
+	High             string
+	Low              string
+	Close            string
+	Volume           string
+	RawOpen          string
+	RawHigh          string
+	RawLow           string
+	RawClose         string
+	AdjustedClose    string
+	Dividend         string
+	SplitCoefficient string
+	SentimentScore   float64
+	SentimentLabel   string
+	NewsCount        int
+	NewsTitles       string
 }
 
 type OpenFIGIRequest []struct {
@@ -65,20 +62,69 @@ func main() {
 	days := flag.Int("days", 365, "Number of days to fetch data for")
 	outputDir := flag.String("output-dir", ".", "Output directory for CSV files")
 	apiKey := flag.String("apikey", os.Getenv("ALPHAVANTAGE_API_KEY"), "Alpha Vantage API key")
+	provider := flag.String("provider", "auto", "Quote provider to use: auto, yahoo or alphavantage")
+	cacheDB := flag.String("cache-db", "./stocktrack.db", "Path to the SQLite cache database")
+	refresh := flag.Bool("refresh", false, "Ignore cached data and re-fetch everything")
+	maxAge := flag.Duration("max-age", 0, "Invalidate cached rows older than this duration (0 = never expire)")
+	serveAddr := flag.String("serve", "", "Run as an HTTP server listening on this address (e.g. :8080) instead of a single one-shot fetch")
+	sentimentThreshold := flag.Float64("sentiment-threshold", 0.3, "Minimum ticker relevance_score for a news article to be counted")
+	adjusted := flag.Bool("adjusted", false, "Fetch split/dividend-adjusted prices (Alpha Vantage only)")
+	portfolioFile := flag.String("portfolio", "", "Path to a portfolio file (CSV or newline-delimited identifiers) to process in batch mode")
+	concurrency := flag.Int("concurrency", 4, "Number of tickers to process concurrently in -portfolio mode")
 	flag.Parse()
 
+	if *serveAddr != "" {
+		cache, err := OpenCache(*cacheDB, *refresh, *maxAge)
+		if err != nil {
+			fmt.Printf("Error opening cache: %v\n", err)
+			return
+		}
+		defer cache.Close()
+
+		if err := serve(*serveAddr, cache, *apiKey, *provider, *adjusted, *sentimentThreshold); err != nil {
+			fmt.Printf("Error running server: %v\n", err)
+		}
+		return
+	}
+
+	if *portfolioFile != "" {
+		if *concurrency < 1 {
+			fmt.Println("Please provide a -concurrency of at least 1")
+			return
+		}
+
+		cache, err := OpenCache(*cacheDB, *refresh, *maxAge)
+		if err != nil {
+			fmt.Printf("Error opening cache: %v\n", err)
+			return
+		}
+		defer cache.Close()
+
+		if err := runPortfolio(*portfolioFile, cache, *provider, *apiKey, *outputDir, *sentimentThreshold, *concurrency); err != nil {
+			fmt.Printf("Error processing portfolio: %v\n", err)
+		}
+		return
+	}
+
 	if *identifier == "" {
 		fmt.Println("Please provide a stock identifier using -id flag")
 		return
 	}
 
-	if *apiKey == "" {
+	if *provider == "alphavantage" && *apiKey == "" {
 		fmt.Println("Please provide an Alpha Vantage API key either:")
 		fmt.Println("- in .env file as ALPHAVANTAGE_API_KEY=your_key")
 		fmt.Println("- or using -apikey flag")
 		return
 	}
 
+	cache, err := OpenCache(*cacheDB, *refresh, *maxAge)
+	if err != nil {
+		fmt.Printf("Error opening cache: %v\n", err)
+		return
+	}
+	defer cache.Close()
+
 	symbol, err := getTickerSymbol(*identifier)
 	if err != nil {
 		fmt.Printf("Error looking up ticker symbol: %v\n", err)
@@ -87,14 +133,16 @@ func main() {
 
 	fmt.Printf("Found ticker symbol: %s\n", symbol)
 
-	data, err := fetchStockData(symbol, *days, *apiKey)
+	data, err := fetchStockData(cache, symbol, *days, *provider, *adjusted, *apiKey, nil)
 	if err != nil {
 		fmt.Printf("Error fetching stock data: %v\n", err)
 		return
 	}
 
 	// Fetch and merge news data
-	if err := enrichWithNews(symbol, *apiKey, data); err != nil {
+	to := time.Now()
+	from := to.AddDate(0, 0, -*days)
+	if err := enrichWithNews(cache, symbol, *apiKey, data, from, to, *sentimentThreshold, nil); err != nil {
 		fmt.Printf("Warning: Error fetching news data: %v\n", err)
 		// Continue anyway as we still have price data
 	}
@@ -108,56 +156,151 @@ func main() {
 	fmt.Printf("Successfully saved stock data to %s\n", filename)
 }
 
-func enrichWithNews(symbol, apiKey string, data []StockData) error {
+// enrichWithNews aggregates, per date, the news articles relevant to
+// symbol into SentimentScore, SentimentLabel, NewsCount and
+// NewsTitles on the matching StockData row. limiter is passed through
+// to newsForSymbol and only applies on a cache miss.
+func enrichWithNews(cache *Cache, symbol, apiKey string, data []StockData, from, to time.Time, minRelevance float64, limiter *RateLimiter) error {
+	items, err := newsForSymbol(cache, symbol, apiKey, from, to, minRelevance, limiter)
+	if err != nil {
+		return err
+	}
+
+	byDate := make(map[string][]NewsItem)
+	for _, item := range items {
+		byDate[item.Date] = append(byDate[item.Date], item)
+	}
+
+	for i := range data {
+		dayItems, exists := byDate[data[i].Date]
+		if !exists || len(dayItems) == 0 {
+			continue
+		}
+
+		var scoreSum float64
+		titles := make([]string, len(dayItems))
+		for j, item := range dayItems {
+			scoreSum += item.SentimentScore
+			titles[j] = item.Title
+		}
+
+		avgScore := scoreSum / float64(len(dayItems))
+		data[i].SentimentScore = avgScore
+		data[i].SentimentLabel = sentimentLabelForScore(avgScore)
+		data[i].NewsCount = len(dayItems)
+		data[i].NewsTitles = strings.Join(titles, "; ")
+	}
+
+	return nil
+}
+
+// sentimentLabelForScore buckets a sentiment score using the same
+// thresholds Alpha Vantage documents for its own overall_sentiment_label.
+func sentimentLabelForScore(score float64) string {
+	switch {
+	case score <= -0.35:
+		return "Bearish"
+	case score <= -0.15:
+		return "Somewhat-Bearish"
+	case score < 0.15:
+		return "Neutral"
+	case score < 0.35:
+		return "Somewhat-Bullish"
+	default:
+		return "Bullish"
+	}
+}
+
+// newsForSymbol returns the news articles relevant to symbol within
+// [from, to], serving them from cache when possible and falling back
+// to Alpha Vantage otherwise. limiter only gates the Alpha Vantage
+// request, not the cache lookup.
+func newsForSymbol(cache *Cache, symbol, apiKey string, from, to time.Time, minRelevance float64, limiter *RateLimiter) ([]NewsItem, error) {
+	items, covers := cache.News(symbol, from, to)
+	if covers {
+		return items, nil
+	}
+
+	fetched, err := fetchNewsArticles(symbol, apiKey, minRelevance, limiter)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cache.PutNews(symbol, fetched); err != nil {
+		fmt.Printf("Warning: failed to cache news for %s: %v\n", symbol, err)
+	}
+
+	fromDate, toDate := from.Format("2006-01-02"), to.Format("2006-01-02")
+	var inWindow []NewsItem
+	for _, item := range fetched {
+		if item.Date >= fromDate && item.Date <= toDate {
+			inWindow = append(inWindow, item)
+		}
+	}
+
+	return inWindow, nil
+}
+
+// fetchNewsArticles calls Alpha Vantage's NEWS_SENTIMENT endpoint and
+// returns only the articles whose ticker_sentiment entry for symbol
+// has relevance_score >= minRelevance, carrying that entry's
+// ticker-specific sentiment score and label. limiter, if set, is
+// waited on immediately before the request, not before the cache
+// lookup in newsForSymbol.
+func fetchNewsArticles(symbol, apiKey string, minRelevance float64, limiter *RateLimiter) ([]NewsItem, error) {
 	url := fmt.Sprintf("https://www.alphavantage.co/query?function=NEWS_SENTIMENT&tickers=%s&apikey=%s",
 		symbol, apiKey)
 
+	limiter.Wait()
 	resp, err := http.Get(url)
 	if err != nil {
-		return fmt.Errorf("error making news request: %v", err)
+		return nil, fmt.Errorf("error making news request: %v", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("error reading news response: %v", err)
+		return nil, fmt.Errorf("error reading news response: %v", err)
 	}
 
 	var result AlphaVantageNewsResponse
 	if err := json.Unmarshal(body, &result); err != nil {
-		return fmt.Errorf("error parsing news JSON: %v", err)
+		return nil, fmt.Errorf("error parsing news JSON: %v", err)
 	}
 
-	// Create a map of date to news items
-	newsMap := make(map[string][]string)
+	var items []NewsItem
 	for _, item := range result.Feed {
-		// Parse the timestamp (format: 20240308T130000)
 		t, err := time.Parse("20060102T150405", item.TimePublished)
 		if err != nil {
 			continue
 		}
-		date := t.Format("2006-01-02")
 
-		// Combine title and summary
-		news := fmt.Sprintf("%s - %s", item.Title, item.Summary)
-		newsMap[date] = append(newsMap[date], news)
-	}
+		for _, ts := range item.TickerSentiment {
+			if ts.Ticker != symbol {
+				continue
+			}
 
-	// Merge news with stock data
-	for i := range data {
-		if news, exists := newsMap[data[i].Date]; exists && len(news) > 0 {
-			// Take the first news item for the day
-			parts := strings.SplitN(news[0], " - ", 2)
-			if len(parts) == 2 {
-				data[i].NewsTitle = parts[0]
-				data[i].NewsSummary = parts[1]
-			} else {
-				data[i].NewsTitle = news[0]
+			relevance, err := strconv.ParseFloat(ts.RelevanceScore, 64)
+			if err != nil || relevance < minRelevance {
+				continue
 			}
+
+			sentimentScore, _ := strconv.ParseFloat(ts.TickerSentimentScore, 64)
+
+			items = append(items, NewsItem{
+				Date:           t.Format("2006-01-02"),
+				URL:            item.URL,
+				Title:          item.Title,
+				Summary:        item.Summary,
+				RelevanceScore: relevance,
+				SentimentScore: sentimentScore,
+				SentimentLabel: ts.TickerSentimentLabel,
+			})
+			break
 		}
 	}
 
-	return nil
+	return items, nil
 }
 
 func getTickerSymbol(identifier string) (string, error) {
@@ -216,56 +359,6 @@ func getTickerSymbol(identifier string) (string, error) {
 	return result[0].Data[0].Ticker, nil
 }
 
-func fetchStockData(symbol string, days int, apiKey string) ([]StockData, error) {
-	url := fmt.Sprintf("https://www.alphavantage.co/query?function=TIME_SERIES_DAILY&symbol=%s&outputsize=full&apikey=%s",
-		symbol, apiKey)
-
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("error making request: %v", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("error reading response: %v", err)
-	}
-
-	var result AlphaVantageResponse
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("error parsing JSON: %v", err)
-	}
-
-	if len(result.TimeSeries) == 0 {
-		return nil, fmt.Errorf("no data returned for symbol %s", symbol)
-	}
-
-	var stockData []StockData
-	cutoffDate := time.Now().AddDate(0, 0, -days)
-
-	for date, data := range result.TimeSeries {
-		parsedDate, err := time.Parse("2006-01-02", date)
-		if err != nil {
-			return nil, fmt.Errorf("error parsing date %s: %v", date, err)
-		}
-
-		if parsedDate.Before(cutoffDate) {
-			continue
-		}
-
-		stockData = append(stockData, StockData{
-			Date:   date,
-			Open:   data.Open,
-			High:   data.High,
-			Low:    data.Low,
-			Close:  data.Close,
-			Volume: data.Volume,
-		})
-	}
-
-	return stockData, nil
-}
-
 func saveToCSV(data []StockData, filename string) error {
 	file, err := os.Create(filename)
 	if err != nil {
@@ -273,10 +366,21 @@ func saveToCSV(data []StockData, filename string) error {
 	}
 	defer file.Close()
 
-	writer := csv.NewWriter(file)
+	return writeCSV(data, file)
+}
+
+// writeCSV writes data in CSV form to w, so callers can target a file
+// or stream it directly to an HTTP response.
+func writeCSV(data []StockData, w io.Writer) error {
+	writer := csv.NewWriter(w)
 	defer writer.Flush()
 
-	header := []string{"Date", "Open", "High", "Low", "Close", "Volume", "News Title", "News Summary"}
+	header := []string{
+		"Date", "Open", "High", "Low", "Close", "Volume",
+		"Raw Open", "Raw High", "Raw Low", "Raw Close",
+		"Adjusted Close", "Dividend", "Split Coefficient",
+		"Sentiment Score", "Sentiment Label", "News Count", "News Titles",
+	}
 	if err := writer.Write(header); err != nil {
 		return err
 	}
@@ -289,8 +393,17 @@ func saveToCSV(data []StockData, filename string) error {
 			record.Low,
 			record.Close,
 			record.Volume,
-			record.NewsTitle,
-			record.NewsSummary,
+			record.RawOpen,
+			record.RawHigh,
+			record.RawLow,
+			record.RawClose,
+			record.AdjustedClose,
+			record.Dividend,
+			record.SplitCoefficient,
+			strconv.FormatFloat(record.SentimentScore, 'f', 4, 64),
+			record.SentimentLabel,
+			strconv.Itoa(record.NewsCount),
+			record.NewsTitles,
 		}
 		if err := writer.Write(row); err != nil {
 			return err
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PortfolioEntry is one holding read from a -portfolio file: an
+// identifier (WKN/ISIN) plus optional quantity and cost basis. Quantity
+// is used to compute position value in the aggregate summary; cost
+// basis is copied into the summary as given.
+type PortfolioEntry struct {
+	Identifier string
+	Quantity   float64
+	CostBasis  float64
+}
+
+// PortfolioResult is the outcome of processing one PortfolioEntry:
+// either a filled-in summary row, or Err describing why it failed.
+// A failed entry never aborts the rest of the batch.
+type PortfolioResult struct {
+	Entry         PortfolioEntry
+	Symbol        string
+	LatestClose   float64
+	DayChangePct  float64
+	PositionValue float64
+	High52Week    float64
+	Low52Week     float64
+	Err           error
+}
+
+// parsePortfolioFile reads a -portfolio input: one holding per line,
+// comma-separated as identifier[,quantity[,cost_basis]] (a bare
+// newline-delimited list of identifiers works too, since the extra
+// columns are optional). Blank lines and lines starting with # are
+// skipped, as is a header row naming its first column identifier,
+// wkn or isin.
+func parsePortfolioFile(path string) ([]PortfolioEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening portfolio file: %v", err)
+	}
+	defer file.Close()
+
+	var entries []PortfolioEntry
+	first := true
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields, err := csv.NewReader(strings.NewReader(line)).Read()
+		if err != nil {
+			return nil, fmt.Errorf("error parsing portfolio line %q: %v", line, err)
+		}
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+
+		if first {
+			first = false
+			switch strings.ToLower(fields[0]) {
+			case "identifier", "wkn", "isin":
+				continue
+			}
+		}
+
+		entry := PortfolioEntry{Identifier: fields[0]}
+
+		if len(fields) > 1 && fields[1] != "" {
+			entry.Quantity, err = strconv.ParseFloat(fields[1], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid quantity %q for %s: %v", fields[1], fields[0], err)
+			}
+		}
+
+		if len(fields) > 2 && fields[2] != "" {
+			entry.CostBasis, err = strconv.ParseFloat(fields[2], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid cost basis %q for %s: %v", fields[2], fields[0], err)
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading portfolio file: %v", err)
+	}
+
+	return entries, nil
+}
+
+// RateLimiter is a token bucket used to keep batch requests under
+// Alpha Vantage's free-tier limit of 5 requests per minute.
+type RateLimiter struct {
+	tokens chan struct{}
+}
+
+// NewRateLimiter returns a limiter allowing ratePerMinute tokens,
+// refilled one at a time at an even pace across each minute, with an
+// initial burst of ratePerMinute.
+func NewRateLimiter(ratePerMinute int) *RateLimiter {
+	rl := &RateLimiter{tokens: make(chan struct{}, ratePerMinute)}
+	for i := 0; i < ratePerMinute; i++ {
+		rl.tokens <- struct{}{}
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Minute / time.Duration(ratePerMinute))
+		defer ticker.Stop()
+		for range ticker.C {
+			select {
+			case rl.tokens <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	return rl
+}
+
+// Wait blocks until a token is available. A nil *RateLimiter is a
+// no-op, so callers that don't need rate limiting can pass nil.
+func (rl *RateLimiter) Wait() {
+	if rl == nil {
+		return
+	}
+	<-rl.tokens
+}
+
+// runPortfolio drives -portfolio mode end to end: parse the input
+// file, fan the holdings out across a bounded worker pool, write a
+// per-symbol CSV plus an aggregate portfolio_summary.csv into
+// outputDir, and print a short failure report. Individual ticker
+// failures are collected, not fatal to the batch.
+func runPortfolio(path string, cache *Cache, provider, apiKey, outputDir string, sentimentThreshold float64, concurrency int) error {
+	entries, err := parsePortfolioFile(path)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Processing %d holdings with concurrency %d...\n", len(entries), concurrency)
+
+	limiter := NewRateLimiter(5)
+	results := make([]PortfolioResult, len(entries))
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, entry := range entries {
+		wg.Add(1)
+		go func(i int, entry PortfolioEntry) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i] = processPortfolioEntry(entry, cache, provider, apiKey, outputDir, sentimentThreshold, limiter)
+		}(i, entry)
+	}
+	wg.Wait()
+
+	if err := writePortfolioSummary(results, outputDir); err != nil {
+		return fmt.Errorf("error writing portfolio summary: %v", err)
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+			fmt.Printf("Failed: %s: %v\n", r.Entry.Identifier, r.Err)
+		}
+	}
+
+	fmt.Printf("Done: %d succeeded, %d failed out of %d holdings\n", len(results)-failed, failed, len(results))
+	return nil
+}
+
+// processPortfolioEntry resolves, fetches and writes the CSV for a
+// single holding, and computes the metrics that go into the aggregate
+// summary. It always fetches a full 52-week window regardless of the
+// global -days flag, since the summary reports 52-week high/low.
+// limiter is only waited on by the actual Alpha Vantage HTTP calls
+// inside fetchStockData/enrichWithNews, so ticker lookups, cache hits
+// and Yahoo fetches aren't throttled by it.
+func processPortfolioEntry(entry PortfolioEntry, cache *Cache, provider, apiKey, outputDir string, sentimentThreshold float64, limiter *RateLimiter) PortfolioResult {
+	result := PortfolioResult{Entry: entry}
+
+	symbol, err := getTickerSymbol(entry.Identifier)
+	if err != nil {
+		result.Err = fmt.Errorf("ticker lookup: %v", err)
+		return result
+	}
+	result.Symbol = symbol
+
+	const days = 365
+
+	data, err := fetchStockData(cache, symbol, days, provider, false, apiKey, limiter)
+	if err != nil {
+		result.Err = fmt.Errorf("fetching prices: %v", err)
+		return result
+	}
+	if len(data) == 0 {
+		result.Err = fmt.Errorf("no price data returned")
+		return result
+	}
+
+	to := time.Now()
+	from := to.AddDate(0, 0, -days)
+
+	if err := enrichWithNews(cache, symbol, apiKey, data, from, to, sentimentThreshold, limiter); err != nil {
+		fmt.Printf("Warning: Error fetching news data for %s: %v\n", symbol, err)
+	}
+
+	sort.Slice(data, func(i, j int) bool { return data[i].Date < data[j].Date })
+
+	latestClose, _ := strconv.ParseFloat(data[len(data)-1].Close, 64)
+	result.LatestClose = latestClose
+	result.PositionValue = latestClose * entry.Quantity
+
+	if len(data) >= 2 {
+		if prevClose, err := strconv.ParseFloat(data[len(data)-2].Close, 64); err == nil && prevClose != 0 {
+			result.DayChangePct = (latestClose - prevClose) / prevClose * 100
+		}
+	}
+
+	result.High52Week, result.Low52Week = highLow(data)
+
+	filename := fmt.Sprintf("%s/%s.csv", outputDir, symbol)
+	if err := saveToCSV(data, filename); err != nil {
+		result.Err = fmt.Errorf("saving CSV: %v", err)
+	}
+
+	return result
+}
+
+func highLow(data []StockData) (high, low float64) {
+	for i, row := range data {
+		h, errH := strconv.ParseFloat(row.High, 64)
+		l, errL := strconv.ParseFloat(row.Low, 64)
+
+		if i == 0 {
+			high, low = h, l
+		}
+		if errH == nil && h > high {
+			high = h
+		}
+		if errL == nil && l < low {
+			low = l
+		}
+	}
+
+	return high, low
+}
+
+// writePortfolioSummary writes one row per PortfolioResult with the
+// computed position metrics, plus an Error column for any that failed.
+func writePortfolioSummary(results []PortfolioResult, outputDir string) error {
+	file, err := os.Create(fmt.Sprintf("%s/portfolio_summary.csv", outputDir))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	header := []string{
+		"Identifier", "Symbol", "Quantity", "Cost Basis",
+		"Latest Close", "Day Change %", "Position Value",
+		"52 Week High", "52 Week Low", "Error",
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, r := range results {
+		errMsg := ""
+		if r.Err != nil {
+			errMsg = r.Err.Error()
+		}
+
+		row := []string{
+			r.Entry.Identifier,
+			r.Symbol,
+			strconv.FormatFloat(r.Entry.Quantity, 'f', -1, 64),
+			strconv.FormatFloat(r.Entry.CostBasis, 'f', -1, 64),
+			strconv.FormatFloat(r.LatestClose, 'f', 4, 64),
+			strconv.FormatFloat(r.DayChangePct, 'f', 4, 64),
+			strconv.FormatFloat(r.PositionValue, 'f', 4, 64),
+			strconv.FormatFloat(r.High52Week, 'f', 4, 64),
+			strconv.FormatFloat(r.Low52Week, 'f', 4, 64),
+			errMsg,
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// QuoteProvider fetches daily OHLCV data for a symbol over a date range.
+// Implementations normalize whatever upstream format they speak into
+// the shared StockData shape.
+type QuoteProvider interface {
+	Name() string
+	FetchDaily(symbol string, from, to time.Time) ([]StockData, error)
+}
+
+// fetchStockData resolves the requested provider mode into a concrete
+// QuoteProvider (or providers, for "auto") and returns the last `days`
+// worth of daily data for symbol, consulting cache first and upserting
+// whatever is freshly fetched back into it.
+//
+// In "auto" mode Yahoo Finance is tried first since it requires no API
+// key; Alpha Vantage is used as a fallback when Yahoo returns no data
+// or errors. "-provider=alphavantage" forces Alpha Vantage directly,
+// and "-provider=yahoo" forces Yahoo with no fallback. adjusted
+// switches Alpha Vantage to its split/dividend-aware endpoint and is
+// incompatible with Yahoo, so it always forces the alphavantage
+// provider; adjusted series also bypass the cache, since the back-
+// applied split adjustment depends on when it was computed. limiter,
+// if set, only gates the Alpha Vantage HTTP request itself, so a cache
+// hit or a Yahoo fetch never waits on it.
+func fetchStockData(cache *Cache, symbol string, days int, providerMode string, adjusted bool, apiKey string, limiter *RateLimiter) ([]StockData, error) {
+	to := time.Now()
+	from := to.AddDate(0, 0, -days)
+
+	if adjusted {
+		return fetchStockDataFromProvider(symbol, from, to, providerMode, adjusted, apiKey, limiter)
+	}
+
+	if data, covers := cache.Prices(symbol, from, to); covers {
+		return data, nil
+	}
+
+	data, err := fetchStockDataFromProvider(symbol, from, to, providerMode, adjusted, apiKey, limiter)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cache.PutPrices(symbol, data); err != nil {
+		fmt.Printf("Warning: failed to cache prices for %s: %v\n", symbol, err)
+	}
+
+	return data, nil
+}
+
+func fetchStockDataFromProvider(symbol string, from, to time.Time, providerMode string, adjusted bool, apiKey string, limiter *RateLimiter) ([]StockData, error) {
+	yahoo := &YahooProvider{}
+	alphaVantage := &AlphaVantageProvider{APIKey: apiKey, Adjusted: adjusted, Limiter: limiter}
+
+	if adjusted && providerMode != "alphavantage" {
+		fmt.Println("Adjusted prices are only available from Alpha Vantage; forcing -provider=alphavantage")
+		providerMode = "alphavantage"
+	}
+
+	switch providerMode {
+	case "yahoo":
+		return yahoo.FetchDaily(symbol, from, to)
+	case "alphavantage":
+		return alphaVantage.FetchDaily(symbol, from, to)
+	case "auto", "":
+		data, err := yahoo.FetchDaily(symbol, from, to)
+		if err == nil && len(data) > 0 {
+			return data, nil
+		}
+		fmt.Printf("Yahoo Finance unavailable (%v), falling back to Alpha Vantage\n", err)
+		return alphaVantage.FetchDaily(symbol, from, to)
+	default:
+		return nil, fmt.Errorf("unknown provider %q (expected auto, yahoo or alphavantage)", providerMode)
+	}
+}
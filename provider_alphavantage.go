@@ -0,0 +1,223 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+type AlphaVantageNewsResponse struct {
+	Feed []struct {
+		Title                 string  `json:"title"`
+		URL                   string  `json:"url"`
+		TimePublished         string  `json:"time_published"`
+		Summary               string  `json:"summary"`
+		OverallSentimentScore float64 `json:"overall_sentiment_score"`
+		OverallSentimentLabel string  `json:"overall_sentiment_label"`
+		TickerSentiment       []struct {
+			Ticker               string `json:"ticker"`
+			RelevanceScore       string `json:"relevance_score"`
+			TickerSentimentScore string `json:"ticker_sentiment_score"`
+			TickerSentimentLabel string `json:"ticker_sentiment_label"`
+		} `json:"ticker_sentiment"`
+	} `json:"feed"`
+}
+
+type AlphaVantageResponse struct {
+	TimeSeries map[string]struct {
+		Open   string `json:"1. open"`
+		High   string `json:"2. high"`
+		Low    string `json:"3. low"`
+		Close  string `json:"4. close"`
+		Volume string `json:"5. volume"`
+	} `json:"Time Series (Daily)"`
+}
+
+type AlphaVantageAdjustedResponse struct {
+	TimeSeries map[string]struct {
+		Open             string `json:"1. open"`
+		High             string `json:"2. high"`
+		Low              string `json:"3. low"`
+		Close            string `json:"4. close"`
+		AdjustedClose    string `json:"5. adjusted close"`
+		Volume           string `json:"6. volume"`
+		DividendAmount   string `json:"7. dividend amount"`
+		SplitCoefficient string `json:"8. split coefficient"`
+	} `json:"Time Series (Daily)"`
+}
+
+// AlphaVantageProvider fetches daily quotes from Alpha Vantage's
+// TIME_SERIES_DAILY endpoint (or TIME_SERIES_DAILY_ADJUSTED when
+// Adjusted is set). It requires an API key and is subject to Alpha
+// Vantage's free-tier rate limits. Limiter, if set, is waited on
+// immediately before each HTTP request, not before cache lookups.
+type AlphaVantageProvider struct {
+	APIKey   string
+	Adjusted bool
+	Limiter  *RateLimiter
+}
+
+func (p *AlphaVantageProvider) Name() string {
+	return "alphavantage"
+}
+
+func (p *AlphaVantageProvider) FetchDaily(symbol string, from, to time.Time) ([]StockData, error) {
+	if p.APIKey == "" {
+		return nil, fmt.Errorf("alpha vantage API key is required")
+	}
+
+	if p.Adjusted {
+		return p.fetchDailyAdjusted(symbol, from, to)
+	}
+
+	url := fmt.Sprintf("https://www.alphavantage.co/query?function=TIME_SERIES_DAILY&symbol=%s&outputsize=full&apikey=%s",
+		symbol, p.APIKey)
+
+	p.Limiter.Wait()
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %v", err)
+	}
+
+	var result AlphaVantageResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("error parsing JSON: %v", err)
+	}
+
+	if len(result.TimeSeries) == 0 {
+		return nil, fmt.Errorf("no data returned for symbol %s", symbol)
+	}
+
+	var stockData []StockData
+
+	for date, data := range result.TimeSeries {
+		parsedDate, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing date %s: %v", date, err)
+		}
+
+		if parsedDate.Before(from) || parsedDate.After(to) {
+			continue
+		}
+
+		stockData = append(stockData, StockData{
+			Date:   date,
+			Open:   data.Open,
+			High:   data.High,
+			Low:    data.Low,
+			Close:  data.Close,
+			Volume: data.Volume,
+		})
+	}
+
+	return stockData, nil
+}
+
+// fetchDailyAdjusted fetches TIME_SERIES_DAILY_ADJUSTED, which carries
+// the adjusted close, dividend amount and split coefficient Alpha
+// Vantage computes for each day, then back-applies the split
+// coefficients across the series so Open/High/Low/Close become
+// split-adjusted too (AdjustedClose already accounts for dividends on
+// top of that, for accurate total-return calculations). The original,
+// as-quoted values are preserved in RawOpen/RawHigh/RawLow/RawClose so
+// callers still have the unadjusted series alongside the adjusted one.
+func (p *AlphaVantageProvider) fetchDailyAdjusted(symbol string, from, to time.Time) ([]StockData, error) {
+	url := fmt.Sprintf("https://www.alphavantage.co/query?function=TIME_SERIES_DAILY_ADJUSTED&symbol=%s&outputsize=full&apikey=%s",
+		symbol, p.APIKey)
+
+	p.Limiter.Wait()
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %v", err)
+	}
+
+	var result AlphaVantageAdjustedResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("error parsing JSON: %v", err)
+	}
+
+	if len(result.TimeSeries) == 0 {
+		return nil, fmt.Errorf("no data returned for symbol %s", symbol)
+	}
+
+	var stockData []StockData
+
+	for date, data := range result.TimeSeries {
+		parsedDate, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing date %s: %v", date, err)
+		}
+
+		if parsedDate.Before(from) || parsedDate.After(to) {
+			continue
+		}
+
+		stockData = append(stockData, StockData{
+			Date:             date,
+			Open:             data.Open,
+			High:             data.High,
+			Low:              data.Low,
+			Close:            data.Close,
+			Volume:           data.Volume,
+			AdjustedClose:    data.AdjustedClose,
+			Dividend:         data.DividendAmount,
+			SplitCoefficient: data.SplitCoefficient,
+		})
+	}
+
+	applySplitAdjustment(stockData)
+
+	return stockData, nil
+}
+
+// applySplitAdjustment sorts data chronologically and, walking
+// backwards from the newest row, scales every older row's
+// Open/High/Low/Close by the cumulative split factor of every split
+// event newer than it. The as-quoted values are preserved in
+// RawOpen/RawHigh/RawLow/RawClose first, so the split adjustment never
+// destroys the original series.
+func applySplitAdjustment(data []StockData) {
+	sort.Slice(data, func(i, j int) bool { return data[i].Date < data[j].Date })
+
+	cumulativeFactor := 1.0
+	for i := len(data) - 1; i >= 0; i-- {
+		row := &data[i]
+
+		row.RawOpen, row.RawHigh, row.RawLow, row.RawClose = row.Open, row.High, row.Low, row.Close
+
+		if cumulativeFactor != 1.0 {
+			row.Open = scalePrice(row.Open, cumulativeFactor)
+			row.High = scalePrice(row.High, cumulativeFactor)
+			row.Low = scalePrice(row.Low, cumulativeFactor)
+			row.Close = scalePrice(row.Close, cumulativeFactor)
+		}
+
+		if coeff, err := strconv.ParseFloat(row.SplitCoefficient, 64); err == nil && coeff != 0 && coeff != 1.0 {
+			cumulativeFactor *= 1.0 / coeff
+		}
+	}
+}
+
+func scalePrice(raw string, factor float64) string {
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return raw
+	}
+	return strconv.FormatFloat(value*factor, 'f', 4, 64)
+}
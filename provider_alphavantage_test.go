@@ -0,0 +1,71 @@
+package main
+
+import "testing"
+
+func TestApplySplitAdjustment(t *testing.T) {
+	// A synthetic three-day series with a 2-for-1 split effective on
+	// the middle date, and an unrecognized ("0") split coefficient on
+	// the oldest date that must not be treated as a real split. Rows
+	// are given in reverse-chronological order to also exercise the
+	// sort inside applySplitAdjustment.
+	data := []StockData{
+		{Date: "2024-01-03", Open: "102", High: "103", Low: "101", Close: "102", SplitCoefficient: "1"},
+		{Date: "2024-01-02", Open: "50", High: "55", Low: "45", Close: "50", SplitCoefficient: "2"},
+		{Date: "2024-01-01", Open: "100", High: "110", Low: "90", Close: "100", SplitCoefficient: "0"},
+	}
+
+	applySplitAdjustment(data)
+
+	if got := []string{data[0].Date, data[1].Date, data[2].Date}; got[0] != "2024-01-01" || got[2] != "2024-01-03" {
+		t.Fatalf("expected chronological order, got %v", got)
+	}
+
+	// Newest date has no split ahead of it, so it is left unscaled.
+	newest := data[2]
+	if newest.Open != "102" || newest.Close != "102" {
+		t.Errorf("newest row should be unscaled, got Open=%s Close=%s", newest.Open, newest.Close)
+	}
+	if newest.RawOpen != "102" || newest.RawClose != "102" {
+		t.Errorf("RawOpen/RawClose should always be preserved, got RawOpen=%s RawClose=%s", newest.RawOpen, newest.RawClose)
+	}
+
+	// Middle date carries the 2-for-1 split coefficient itself, so it
+	// applies to older rows, not to itself.
+	middle := data[1]
+	if middle.Open != "50" || middle.Close != "50" {
+		t.Errorf("split date itself should be unscaled, got Open=%s Close=%s", middle.Open, middle.Close)
+	}
+
+	// Oldest date predates the split, so it should be halved; its
+	// bogus "0" coefficient must not introduce a second adjustment.
+	oldest := data[0]
+	if oldest.Open != "50.0000" || oldest.High != "55.0000" || oldest.Low != "45.0000" || oldest.Close != "50.0000" {
+		t.Errorf("oldest row should be scaled by 0.5, got Open=%s High=%s Low=%s Close=%s",
+			oldest.Open, oldest.High, oldest.Low, oldest.Close)
+	}
+	if oldest.RawOpen != "100" || oldest.RawHigh != "110" || oldest.RawLow != "90" || oldest.RawClose != "100" {
+		t.Errorf("raw fields must keep the as-quoted values, got RawOpen=%s RawHigh=%s RawLow=%s RawClose=%s",
+			oldest.RawOpen, oldest.RawHigh, oldest.RawLow, oldest.RawClose)
+	}
+}
+
+func TestScalePrice(t *testing.T) {
+	cases := []struct {
+		name   string
+		raw    string
+		factor float64
+		want   string
+	}{
+		{"halves under a 2-for-1 split factor", "100", 0.5, "50.0000"},
+		{"identity factor leaves value unchanged", "123.4567", 1.0, "123.4567"},
+		{"non-numeric input is returned as-is", "n/a", 0.5, "n/a"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := scalePrice(c.raw, c.factor); got != c.want {
+				t.Errorf("scalePrice(%q, %v) = %q, want %q", c.raw, c.factor, got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// yahooChartResponse models the subset of Yahoo Finance's
+// v8/finance/chart response we care about: a list of UNIX timestamps
+// plus parallel OHLCV arrays.
+type yahooChartResponse struct {
+	Chart struct {
+		Result []struct {
+			Timestamp  []int64 `json:"timestamp"`
+			Indicators struct {
+				Quote []struct {
+					Open   []float64 `json:"open"`
+					High   []float64 `json:"high"`
+					Low    []float64 `json:"low"`
+					Close  []float64 `json:"close"`
+					Volume []int64   `json:"volume"`
+				} `json:"quote"`
+			} `json:"indicators"`
+		} `json:"result"`
+		Error *struct {
+			Code        string `json:"code"`
+			Description string `json:"description"`
+		} `json:"error"`
+	} `json:"chart"`
+}
+
+// YahooProvider fetches daily quotes from Yahoo Finance's chart API.
+// It requires no API key, which makes it a good default before
+// falling back to a rate-limited, key-gated provider.
+type YahooProvider struct{}
+
+func (p *YahooProvider) Name() string {
+	return "yahoo"
+}
+
+func (p *YahooProvider) FetchDaily(symbol string, from, to time.Time) ([]StockData, error) {
+	url := fmt.Sprintf("https://query1.finance.yahoo.com/v8/finance/chart/%s?period1=%d&period2=%d&interval=1d",
+		symbol, from.Unix(), to.Unix())
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %v", err)
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; stocktrack/1.0)")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %v", err)
+	}
+
+	var result yahooChartResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("error parsing JSON: %v", err)
+	}
+
+	if result.Chart.Error != nil {
+		return nil, fmt.Errorf("yahoo finance error: %s", result.Chart.Error.Description)
+	}
+
+	if len(result.Chart.Result) == 0 || len(result.Chart.Result[0].Indicators.Quote) == 0 {
+		return nil, fmt.Errorf("no data returned for symbol %s", symbol)
+	}
+
+	r := result.Chart.Result[0]
+	quote := r.Indicators.Quote[0]
+
+	var stockData []StockData
+
+	for i, ts := range r.Timestamp {
+		if i >= len(quote.Close) {
+			break
+		}
+
+		stockData = append(stockData, StockData{
+			Date:   time.Unix(ts, 0).UTC().Format("2006-01-02"),
+			Open:   fmt.Sprintf("%.4f", quote.Open[i]),
+			High:   fmt.Sprintf("%.4f", quote.High[i]),
+			Low:    fmt.Sprintf("%.4f", quote.Low[i]),
+			Close:  fmt.Sprintf("%.4f", quote.Close[i]),
+			Volume: fmt.Sprintf("%d", quote.Volume[i]),
+		})
+	}
+
+	return stockData, nil
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// symbolPattern constrains resolved ticker symbols before they're
+// interpolated into upstream URLs.
+var symbolPattern = regexp.MustCompile(`^[A-Za-z0-9.\-]{1,10}$`)
+
+type apiErrorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(apiErrorResponse{Error: message})
+}
+
+// serve runs stocktrack as an HTTP service exposing quotes, news and
+// CSV export as endpoints, so other apps can query it without
+// shelling out to the CLI.
+func serve(addr string, cache *Cache, apiKey, provider string, adjusted bool, sentimentThreshold float64) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", handleHealthz)
+	mux.HandleFunc("/quote", handleQuote(cache, apiKey, provider, adjusted))
+	mux.HandleFunc("/news", handleNews(cache, apiKey, sentimentThreshold))
+	mux.HandleFunc("/export.csv", handleExportCSV(cache, apiKey, provider, adjusted, sentimentThreshold))
+
+	fmt.Printf("Listening on %s\n", addr)
+	return http.ListenAndServe(addr, mux)
+}
+
+func handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// resolveSymbolAndWindow validates the request method and the common
+// id/days query parameters shared by /quote, /news and /export.csv,
+// and resolves id to a ticker symbol.
+func resolveSymbolAndWindow(r *http.Request, defaultDays int) (symbol string, from, to time.Time, err error) {
+	if r.Method != http.MethodGet {
+		return "", time.Time{}, time.Time{}, fmt.Errorf("method %s not allowed", r.Method)
+	}
+
+	identifier := r.URL.Query().Get("id")
+	if identifier == "" {
+		return "", time.Time{}, time.Time{}, fmt.Errorf("missing required query parameter: id")
+	}
+
+	days := defaultDays
+	if raw := r.URL.Query().Get("days"); raw != "" {
+		parsed, convErr := strconv.Atoi(raw)
+		if convErr != nil || parsed <= 0 {
+			return "", time.Time{}, time.Time{}, fmt.Errorf("invalid days parameter: %s", raw)
+		}
+		days = parsed
+	}
+
+	symbol, err = getTickerSymbol(identifier)
+	if err != nil {
+		return "", time.Time{}, time.Time{}, err
+	}
+
+	if !symbolPattern.MatchString(symbol) {
+		return "", time.Time{}, time.Time{}, fmt.Errorf("resolved symbol %q failed validation", symbol)
+	}
+
+	to = time.Now()
+	from = to.AddDate(0, 0, -days)
+	return symbol, from, to, nil
+}
+
+func handleQuote(cache *Cache, apiKey, provider string, adjusted bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		symbol, from, to, err := resolveSymbolAndWindow(r, 365)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		data, err := fetchStockData(cache, symbol, int(to.Sub(from).Hours()/24), provider, adjusted, apiKey, nil)
+		if err != nil {
+			writeJSONError(w, http.StatusBadGateway, err.Error())
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(data)
+	}
+}
+
+func handleNews(cache *Cache, apiKey string, sentimentThreshold float64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		symbol, from, to, err := resolveSymbolAndWindow(r, 30)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		items, err := newsForSymbol(cache, symbol, apiKey, from, to, sentimentThreshold, nil)
+		if err != nil {
+			writeJSONError(w, http.StatusBadGateway, err.Error())
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(items)
+	}
+}
+
+func handleExportCSV(cache *Cache, apiKey, provider string, adjusted bool, sentimentThreshold float64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		symbol, from, to, err := resolveSymbolAndWindow(r, 365)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		data, err := fetchStockData(cache, symbol, int(to.Sub(from).Hours()/24), provider, adjusted, apiKey, nil)
+		if err != nil {
+			writeJSONError(w, http.StatusBadGateway, err.Error())
+			return
+		}
+
+		if err := enrichWithNews(cache, symbol, apiKey, data, from, to, sentimentThreshold, nil); err != nil {
+			fmt.Printf("Warning: Error fetching news data for %s: %v\n", symbol, err)
+		}
+
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.csv", symbol))
+		if err := writeCSV(data, w); err != nil {
+			fmt.Printf("Error streaming CSV for %s: %v\n", symbol, err)
+		}
+	}
+}